@@ -0,0 +1,211 @@
+// Package execution provides higher-level trading algorithms built on top
+// of github.com/coinut/api/go_api.CoinutClient: portfolio rebalancing and
+// TWAP order slicing.
+package execution
+
+import (
+    "context"
+    "fmt"
+    "log"
+    "time"
+
+    "github.com/coinut/api/go_api"
+    "github.com/coinut/api/models"
+    "github.com/shopspring/decimal"
+)
+
+// RebalanceOptions configures Rebalance.
+type RebalanceOptions struct {
+    // QuoteCurrency is the currency target weights are valued in, e.g.
+    // "USDT". Defaults to "USDT" when empty.
+    QuoteCurrency string
+
+    // DryRun logs the orders and cancellations Rebalance would have sent
+    // instead of sending them.
+    DryRun bool
+}
+
+// Rebalance reads current balances via client.GetBalanceTyped, values the
+// portfolio in QuoteCurrency using each target currency's last tick, and
+// submits the buys/sells needed to move every currency in targets to its
+// target weight (0.0-1.0) of total portfolio value. Stale open orders on
+// the affected instruments are canceled first via GetOpenOrders and
+// CancelOrders.
+func Rebalance(ctx context.Context, client *coinut_api.CoinutClient, targets map[string]float64, opts RebalanceOptions) error {
+    quote := opts.QuoteCurrency
+    if quote == "" {
+        quote = "USDT"
+    }
+
+    balances, err := client.GetBalanceTyped()
+    if err != nil {
+        return fmt.Errorf("execution: rebalance: fetching balance: %w", err)
+    }
+
+    instIDs := make(map[string]uint32, len(targets))
+    prices := make(map[string]decimal.Decimal, len(targets))
+    for symbol := range targets {
+        if symbol == quote {
+            continue
+        }
+        pair := symbol + quote
+        instID, err := client.GetSpotInstIdCtx(ctx, pair)
+        if err != nil {
+            return fmt.Errorf("execution: rebalance: resolving instrument for %s: %w", pair, err)
+        }
+        tick, err := client.GetInstTickCtx(ctx, instID)
+        if err != nil {
+            return fmt.Errorf("execution: rebalance: fetching tick for %s: %w", pair, err)
+        }
+        parsed, err := models.ParseTick(tick)
+        if err != nil {
+            return fmt.Errorf("execution: rebalance: parsing tick for %s: %w", pair, err)
+        }
+        instIDs[symbol] = instID
+        prices[symbol] = parsed.Last
+    }
+
+    total := balances[quote]
+    for symbol, price := range prices {
+        total = total.Add(balances[symbol].Mul(price))
+    }
+
+    for symbol, instID := range instIDs {
+        openOrders, err := client.GetOpenOrdersCtx(ctx, instID)
+        if err != nil {
+            return fmt.Errorf("execution: rebalance: fetching open orders for %s: %w", symbol, err)
+        }
+        var staleIDs []uint32
+        for _, o := range openOrders {
+            order, ok := o.(map[string]interface{})
+            if !ok {
+                continue
+            }
+            if orderID, ok := order["order_id"].(float64); ok {
+                staleIDs = append(staleIDs, uint32(orderID))
+            }
+        }
+        if len(staleIDs) == 0 {
+            continue
+        }
+        if opts.DryRun {
+            log.Printf("execution: dry-run: would cancel %d stale order(s) on %s", len(staleIDs), symbol)
+            continue
+        }
+        if _, err := client.CancelOrdersCtx(ctx, int(instID), staleIDs); err != nil {
+            return fmt.Errorf("execution: rebalance: canceling stale orders for %s: %w", symbol, err)
+        }
+    }
+
+    for symbol, weight := range targets {
+        if symbol == quote {
+            continue
+        }
+        instID := instIDs[symbol]
+        price := prices[symbol]
+        if price.IsZero() {
+            continue
+        }
+
+        side, qty, ok := rebalanceOrder(total, decimal.NewFromFloat(weight), balances[symbol], price)
+        if !ok {
+            continue
+        }
+
+        if opts.DryRun {
+            log.Printf("execution: dry-run: would %s %.8f %s at ~%s", side, qty, symbol, price.String())
+            continue
+        }
+        if _, err := client.SubmitNewOrderCtx(ctx, instID, side, qty, 0, 0); err != nil {
+            return fmt.Errorf("execution: rebalance: submitting order for %s: %w", symbol, err)
+        }
+    }
+
+    return nil
+}
+
+// rebalanceOrder computes the order needed to move a currency holding
+// from its current balance to weight's share of total portfolio value
+// at price. ok is false when there is nothing to do (the currency is
+// already exactly at its target value).
+func rebalanceOrder(total, weight, balance, price decimal.Decimal) (side string, qty float64, ok bool) {
+    targetValue := total.Mul(weight)
+    currentValue := balance.Mul(price)
+    deltaValue := targetValue.Sub(currentValue)
+    if deltaValue.IsZero() {
+        return "", 0, false
+    }
+
+    side = "BUY"
+    if deltaValue.IsNegative() {
+        side = "SELL"
+    }
+    qty, _ = deltaValue.Abs().Div(price).Float64()
+    return side, qty, true
+}
+
+// TWAPResult tracks what TWAP actually submitted and filled.
+type TWAPResult struct {
+    Orders    []models.OrderResponse
+    FilledQty decimal.Decimal
+}
+
+// TWAP slices a totalQty market order for inst_id into `slices` equal
+// child orders spaced evenly across duration, submitting each via
+// SubmitNewOrderCtx and accumulating their fills into the returned
+// TWAPResult. If dryRun is true, the intended child orders are logged
+// instead of sent.
+func TWAP(ctx context.Context, client *coinut_api.CoinutClient, inst_id uint32, side string, totalQty float64, duration time.Duration, slices int, dryRun bool) (*TWAPResult, error) {
+    if slices <= 0 {
+        return nil, fmt.Errorf("execution: twap: slices must be positive")
+    }
+
+    interval := duration / time.Duration(slices)
+    result := &TWAPResult{}
+
+    for i := 0; i < slices; i++ {
+        qty := twapSliceQty(totalQty, slices, i)
+
+        if dryRun {
+            log.Printf("execution: dry-run: would %s %.8f of inst %d (slice %d/%d)", side, qty, inst_id, i+1, slices)
+        } else {
+            raw, err := client.SubmitNewOrderCtx(ctx, inst_id, side, qty, 0, 0)
+            if err != nil {
+                return result, fmt.Errorf("execution: twap: slice %d/%d: %w", i+1, slices, err)
+            }
+            resp, err := models.ParseOrderResponse(raw)
+            if err != nil {
+                return result, fmt.Errorf("execution: twap: slice %d/%d: %w", i+1, slices, err)
+            }
+            result.Orders = append(result.Orders, resp)
+            switch {
+            case resp.Filled != nil:
+                result.FilledQty = result.FilledQty.Add(resp.Filled.FillQty)
+            case resp.Accepted != nil:
+                result.FilledQty = result.FilledQty.Add(resp.Accepted.Qty.Sub(resp.Accepted.OpenQty))
+            }
+        }
+
+        if i < slices-1 {
+            select {
+            case <-time.After(interval):
+            case <-ctx.Done():
+                return result, ctx.Err()
+            }
+        }
+    }
+
+    return result, nil
+}
+
+// twapSliceQty returns the child order quantity for slice i of slices,
+// splitting totalQty into equal parts except for the last slice, which
+// absorbs whatever remainder equal division leaves behind so the full
+// totalQty is always accounted for.
+func twapSliceQty(totalQty float64, slices, i int) float64 {
+    childQty := totalQty / float64(slices)
+    if i == slices-1 {
+        return totalQty - childQty*float64(slices-1)
+    }
+    return childQty
+}
@@ -0,0 +1,114 @@
+package execution
+
+import (
+    "math"
+    "testing"
+
+    "github.com/shopspring/decimal"
+)
+
+func dec(s string) decimal.Decimal {
+    return decimal.RequireFromString(s)
+}
+
+func TestRebalanceOrder(t *testing.T) {
+    cases := []struct {
+        name           string
+        total, weight  decimal.Decimal
+        balance, price decimal.Decimal
+        wantSide       string
+        wantQty        float64
+        wantOk         bool
+    }{
+        {
+            name:     "underweight buys",
+            total:    dec("1000"),
+            weight:   dec("0.5"),
+            balance:  dec("1"),
+            price:    dec("100"),
+            wantSide: "BUY",
+            wantQty:  4, // target 500, current 100, delta 400 / price 100
+            wantOk:   true,
+        },
+        {
+            name:     "overweight sells",
+            total:    dec("1000"),
+            weight:   dec("0.1"),
+            balance:  dec("5"),
+            price:    dec("100"),
+            wantSide: "SELL",
+            wantQty:  4, // target 100, current 500, delta -400 / price 100
+            wantOk:   true,
+        },
+        {
+            name:    "already at target does nothing",
+            total:   dec("1000"),
+            weight:  dec("0.5"),
+            balance: dec("5"),
+            price:   dec("100"),
+            wantOk:  false,
+        },
+    }
+
+    for _, c := range cases {
+        t.Run(c.name, func(t *testing.T) {
+            side, qty, ok := rebalanceOrder(c.total, c.weight, c.balance, c.price)
+            if ok != c.wantOk {
+                t.Fatalf("ok = %v, want %v", ok, c.wantOk)
+            }
+            if !ok {
+                return
+            }
+            if side != c.wantSide {
+                t.Errorf("side = %q, want %q", side, c.wantSide)
+            }
+            if math.Abs(qty-c.wantQty) > 1e-9 {
+                t.Errorf("qty = %v, want %v", qty, c.wantQty)
+            }
+        })
+    }
+}
+
+func TestTwapSliceQty(t *testing.T) {
+    cases := []struct {
+        name     string
+        totalQty float64
+        slices   int
+        want     []float64
+    }{
+        {
+            name:     "divides evenly",
+            totalQty: 10,
+            slices:   5,
+            want:     []float64{2, 2, 2, 2, 2},
+        },
+        {
+            name:     "last slice absorbs the remainder",
+            totalQty: 1,
+            slices:   3,
+            want:     []float64{1.0 / 3, 1.0 / 3, 1.0 / 3},
+        },
+        {
+            name:     "single slice gets everything",
+            totalQty: 7,
+            slices:   1,
+            want:     []float64{7},
+        },
+    }
+
+    for _, c := range cases {
+        t.Run(c.name, func(t *testing.T) {
+            var sum float64
+            for i := 0; i < c.slices; i++ {
+                got := twapSliceQty(c.totalQty, c.slices, i)
+                if math.Abs(got-c.want[i]) > 1e-9 {
+                    t.Errorf("slice %d = %v, want %v", i, got, c.want[i])
+                }
+                sum += got
+            }
+            if math.Abs(sum-c.totalQty) > 1e-9 {
+                t.Errorf("slices summed to %v, want totalQty %v", sum, c.totalQty)
+            }
+        })
+    }
+}
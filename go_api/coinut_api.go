@@ -1,25 +1,54 @@
 package coinut_api
 
 import (
+    "context"
     "crypto/hmac"
     "crypto/sha256"
     "encoding/hex"
-    "math/rand"
     "encoding/json"
-    "net/http"
-    "io/ioutil"
-    "bytes"
     "fmt"
+    "net/http"
+    "time"
+
+    "github.com/coinut/api/models"
+    "golang.org/x/time/rate"
 )
 
 type CoinutClient struct {
     APIKey string
     User string
+
+    httpClient  *http.Client
+    baseURL     string
+    limiter     *rate.Limiter
+    maxRetries  int
+    logger      Logger
+    nonceSource NonceSource
 }
 
 // initialize the api with user's username and api key
 func NewClient(user string, key string) *CoinutClient {
-    c := &CoinutClient{APIKey: key, User: user}
+    return NewClientWithOptions(user, key)
+}
+
+// NewClientWithOptions initializes the api with user's username and api
+// key, applying any Options on top of the defaults: a 10-second-timeout
+// http.Client, COINUT's documented rate limit, defaultMaxRetries retries
+// on 5xx/429, and a no-op Logger.
+func NewClientWithOptions(user string, key string, opts ...Option) *CoinutClient {
+    c := &CoinutClient{
+        APIKey:      key,
+        User:        user,
+        httpClient:  &http.Client{Timeout: 10 * time.Second},
+        baseURL:     "https://api.coinut.com",
+        limiter:     rate.NewLimiter(rate.Limit(defaultRateLimit), defaultRateLimit),
+        maxRetries:  defaultMaxRetries,
+        logger:      noopLogger{},
+        nonceSource: newAtomicNonce(),
+    }
+    for _, opt := range opts {
+        opt(c)
+    }
     return c
 }
 
@@ -37,8 +66,29 @@ func NewClient(user string, key string) *CoinutClient {
 //
 //    See also:
 //        https://github.com/coinut/api/wiki/Websocket-API#get-account-balance
+//
+//    Deprecated: use GetBalanceTyped, which returns a models.Balance with
+//    decimal amounts instead of a raw map.
 func (client *CoinutClient) GetBalance() (map[string]interface{}, error) {
-    return client.Request("user_balance", "{}")
+    return client.GetBalanceCtx(context.Background())
+}
+
+// GetBalanceCtx is the context-aware equivalent of GetBalance.
+func (client *CoinutClient) GetBalanceCtx(ctx context.Context) (map[string]interface{}, error) {
+    return client.RequestCtx(ctx, "user_balance", "{}")
+}
+
+//    Get my balance, decoded into a models.Balance keyed by currency
+//    symbol.
+//
+//    See also:
+//        https://github.com/coinut/api/wiki/Websocket-API#get-account-balance
+func (client *CoinutClient) GetBalanceTyped() (models.Balance, error) {
+    result, err := client.GetBalance()
+    if err != nil {
+        return nil, err
+    }
+    return models.ParseBalance(result)
 }
 
 //    Get spot trading instruments information
@@ -62,7 +112,13 @@ func (client *CoinutClient) GetBalance() (map[string]interface{}, error) {
 //    See also:
 //        https://github.com/coinut/api/wiki/Websocket-API#get-spot-trading-instruments
 func (client *CoinutClient) GetSpotInstruments(pair string) (interface{}, error) {
-    result, err := client.Request("inst_list", `{"sec_type":"SPOT"}`)
+    return client.GetSpotInstrumentsCtx(context.Background(), pair)
+}
+
+// GetSpotInstrumentsCtx is the context-aware equivalent of
+// GetSpotInstruments.
+func (client *CoinutClient) GetSpotInstrumentsCtx(ctx context.Context, pair string) (interface{}, error) {
+    result, err := client.RequestCtx(ctx, "inst_list", `{"sec_type":"SPOT"}`)
     if err != nil {
         return result, err
     }
@@ -94,7 +150,12 @@ func (client *CoinutClient) GetSpotInstruments(pair string) (interface{}, error)
 //    See also:
 //        https://github.com/coinut/api/wiki/Websocket-API#get-spot-trading-instruments
 func (client *CoinutClient) GetSpotInstId(pair string) (uint32, error) {
-    insts, err := client.GetSpotInstruments(pair)
+    return client.GetSpotInstIdCtx(context.Background(), pair)
+}
+
+// GetSpotInstIdCtx is the context-aware equivalent of GetSpotInstId.
+func (client *CoinutClient) GetSpotInstIdCtx(ctx context.Context, pair string) (uint32, error) {
+    insts, err := client.GetSpotInstrumentsCtx(ctx, pair)
     if err != nil {
         return 0, err
     }
@@ -119,8 +180,31 @@ func (client *CoinutClient) GetSpotInstId(pair string) (uint32, error) {
 //
 //    See also:
 //        https://github.com/coinut/api/wiki/Websocket-API#get-realtime-ticks
+//
+//    Deprecated: use GetInstTickTyped, which returns a *models.Tick with
+//    decimal fields instead of a raw map.
 func (client *CoinutClient) GetInstTick(inst_id uint32) (map[string]interface{}, error) {
-    return client.Request("inst_tick", fmt.Sprintf("{\"inst_id\":%d}", inst_id))
+    return client.GetInstTickCtx(context.Background(), inst_id)
+}
+
+// GetInstTickCtx is the context-aware equivalent of GetInstTick.
+func (client *CoinutClient) GetInstTickCtx(ctx context.Context, inst_id uint32) (map[string]interface{}, error) {
+    return client.RequestCtx(ctx, "inst_tick", fmt.Sprintf("{\"inst_id\":%d}", inst_id))
+}
+
+//    Get a spot trading instrument's last tick, decoded into a
+//    *models.Tick.
+//    Args:
+//        inst_id (uint32): the inst_id can be obtained using the GetSpotInstId or GetSpotInstruments functions.
+//
+//    See also:
+//        https://github.com/coinut/api/wiki/Websocket-API#get-realtime-ticks
+func (client *CoinutClient) GetInstTickTyped(inst_id uint32) (*models.Tick, error) {
+    result, err := client.GetInstTick(inst_id)
+    if err != nil {
+        return nil, err
+    }
+    return models.ParseTick(result)
 }
 
 //    Get a spot trading instrument's orderbook.
@@ -141,8 +225,31 @@ func (client *CoinutClient) GetInstTick(inst_id uint32) (map[string]interface{},
 //
 //    See also:
 //        https://github.com/coinut/api/wiki/Websocket-API#get-orderbooks-in-realtime
+//
+//    Deprecated: use GetOrderbookTyped, which returns a *models.Orderbook
+//    with decimal prices and quantities instead of a raw map.
 func (client *CoinutClient) GetOrderbook(inst_id uint32) (map[string]interface{}, error) {
-    return client.Request("inst_order_book", fmt.Sprintf("{\"inst_id\":%d}", inst_id))
+    return client.GetOrderbookCtx(context.Background(), inst_id)
+}
+
+// GetOrderbookCtx is the context-aware equivalent of GetOrderbook.
+func (client *CoinutClient) GetOrderbookCtx(ctx context.Context, inst_id uint32) (map[string]interface{}, error) {
+    return client.RequestCtx(ctx, "inst_order_book", fmt.Sprintf("{\"inst_id\":%d}", inst_id))
+}
+
+//    Get a spot trading instrument's orderbook, decoded into a
+//    *models.Orderbook.
+//    Args:
+//        inst_id (uint32): the inst_id can be obtained using the GetSpotInstId or GetSpotInstruments functions.
+//
+//    See also:
+//        https://github.com/coinut/api/wiki/Websocket-API#get-orderbooks-in-realtime
+func (client *CoinutClient) GetOrderbookTyped(inst_id uint32) (*models.Orderbook, error) {
+    result, err := client.GetOrderbook(inst_id)
+    if err != nil {
+        return nil, err
+    }
+    return models.ParseOrderbook(result)
 }
 
 //    Get my open orders.
@@ -164,7 +271,12 @@ func (client *CoinutClient) GetOrderbook(inst_id uint32) (map[string]interface{}
 //    See also:
 //        https://github.com/coinut/api/wiki/Websocket-API#get-open-orders
 func (client *CoinutClient) GetOpenOrders(inst_id uint32) ([]interface{}, error) {
-    result, err := client.Request("user_open_orders", fmt.Sprintf("{\"inst_id\":%d}", inst_id))
+    return client.GetOpenOrdersCtx(context.Background(), inst_id)
+}
+
+// GetOpenOrdersCtx is the context-aware equivalent of GetOpenOrders.
+func (client *CoinutClient) GetOpenOrdersCtx(ctx context.Context, inst_id uint32) ([]interface{}, error) {
+    result, err := client.RequestCtx(ctx, "user_open_orders", fmt.Sprintf("{\"inst_id\":%d}", inst_id))
     if err != nil {
         return make([]interface{}, 0), err
     } else {
@@ -209,7 +321,7 @@ func (client *CoinutClient) CreateNewOrder(inst_id uint32,
     if client_ord_id > 0 {
         order["client_ord_id"] = client_ord_id
     } else {
-        order["client_ord_id"] = rand.Uint32()
+        order["client_ord_id"] = randomClientOrderID()
     }
     data, _ := json.Marshal(order)
     return string(data[:]), nil
@@ -237,16 +349,57 @@ func (client *CoinutClient) CreateNewOrder(inst_id uint32,
 //
 //    See also:
 //        https://github.com/coinut/api/wiki/Websocket-API#submit-an-order
+//
+//    Deprecated: use SubmitNewOrderTyped, which returns a
+//    models.OrderResponse discriminated on the reply field instead of a
+//    raw map.
 func (client *CoinutClient) SubmitNewOrder(inst_id uint32,
                       side string,
                       qty float64,
                       price float64,
                       client_ord_id uint32) (map[string]interface{}, error) {
+    return client.SubmitNewOrderCtx(context.Background(), inst_id, side, qty, price, client_ord_id)
+}
+
+// SubmitNewOrderCtx is the context-aware equivalent of SubmitNewOrder.
+func (client *CoinutClient) SubmitNewOrderCtx(ctx context.Context,
+                      inst_id uint32,
+                      side string,
+                      qty float64,
+                      price float64,
+                      client_ord_id uint32) (map[string]interface{}, error) {
     order, err := client.CreateNewOrder(inst_id, side, qty, price, client_ord_id)
     if err != nil {
         return make(map[string]interface{}), err
     }
-    return client.Request("new_order", order)
+    return client.RequestCtx(ctx, "new_order", order)
+}
+
+//    Submit an order to the exchange, decoded into a models.OrderResponse.
+//    Args:
+//        inst_id (uint32): the inst_id can be obtained using the get_spot_inst_id or get_spot_instruments functions.
+//        side (string): either 'BUY' or 'SELL'. It's case sensitive.
+//        qty (float64): the quantity that you want to buy or sell
+//        price (float64): use None to specifcy that the order is a market order; otherwise it's the price of the limit order.
+//        client_ord_id (uint32): an id specified by the client.
+//
+//    Returns:
+//        An OrderResponse whose Reply field is one of "order_accepted",
+//        "order_rejected", or "order_filled", with the corresponding
+//        field populated.
+//
+//    See also:
+//        https://github.com/coinut/api/wiki/Websocket-API#submit-an-order
+func (client *CoinutClient) SubmitNewOrderTyped(inst_id uint32,
+                      side string,
+                      qty float64,
+                      price float64,
+                      client_ord_id uint32) (models.OrderResponse, error) {
+    result, err := client.SubmitNewOrder(inst_id, side, qty, price, client_ord_id)
+    if err != nil {
+        return models.OrderResponse{}, err
+    }
+    return models.ParseOrderResponse(result)
 }
 
 //    Submit orders to the exchange
@@ -271,6 +424,11 @@ func (client *CoinutClient) SubmitNewOrder(inst_id uint32,
 //    See also:
 //        https://github.com/coinut/api/wiki/Websocket-API#submit-orders-in-batch-mode
 func (client *CoinutClient) SubmitNewOrders(ords []string) ([]interface{}, error) {
+    return client.SubmitNewOrdersCtx(context.Background(), ords)
+}
+
+// SubmitNewOrdersCtx is the context-aware equivalent of SubmitNewOrders.
+func (client *CoinutClient) SubmitNewOrdersCtx(ctx context.Context, ords []string) ([]interface{}, error) {
     data := make(map[string]interface{})
     var orders [](map[string]interface{})
     for _, ord := range ords {
@@ -280,7 +438,7 @@ func (client *CoinutClient) SubmitNewOrders(ords []string) ([]interface{}, error
     }
     data["orders"] = orders
     dt, _ := json.Marshal(data)
-    result, err := client.Request("new_orders", string(dt[:]))
+    result, err := client.RequestCtx(ctx, "new_orders", string(dt[:]))
     if err == nil {
         return result["orders"].([]interface{}), nil
     } else {
@@ -308,7 +466,12 @@ func (client *CoinutClient) SubmitNewOrders(ords []string) ([]interface{}, error
 //    See also:
 //        https://github.com/coinut/api/wiki/Websocket-API#cancel-an-order
 func (client *CoinutClient) CancelOrder(inst_id int, order_id uint32) (map[string]interface{}, error) {
-    return client.Request("cancel_order", fmt.Sprintf("{\"inst_id\":%d,\"order_id\":%d}", inst_id, order_id))
+    return client.CancelOrderCtx(context.Background(), inst_id, order_id)
+}
+
+// CancelOrderCtx is the context-aware equivalent of CancelOrder.
+func (client *CoinutClient) CancelOrderCtx(ctx context.Context, inst_id int, order_id uint32) (map[string]interface{}, error) {
+    return client.RequestCtx(ctx, "cancel_order", fmt.Sprintf("{\"inst_id\":%d,\"order_id\":%d}", inst_id, order_id))
 }
 
 //    Cancel orders in a batch
@@ -331,6 +494,11 @@ func (client *CoinutClient) CancelOrder(inst_id int, order_id uint32) (map[strin
 //    See also:
 //        https://github.com/coinut/api/wiki/Websocket-API#cancel-orders-in-batch-mode
 func (client *CoinutClient) CancelOrders(inst_id int, order_ids []uint32) (map[string]interface{}, error) {
+    return client.CancelOrdersCtx(context.Background(), inst_id, order_ids)
+}
+
+// CancelOrdersCtx is the context-aware equivalent of CancelOrders.
+func (client *CoinutClient) CancelOrdersCtx(ctx context.Context, inst_id int, order_ids []uint32) (map[string]interface{}, error) {
     var ords [](map[string]interface{})
     for _, order_id := range order_ids {
       ords = append(ords, map[string]interface{}{"inst_id": inst_id, "order_id": order_id})
@@ -338,7 +506,7 @@ func (client *CoinutClient) CancelOrders(inst_id int, order_ids []uint32) (map[s
     data := make(map[string]interface{})
     data["entries"] = ords
     orders, _ := json.Marshal(data)
-    return client.Request("cancel_orders", string(orders[:]))
+    return client.RequestCtx(ctx, "cancel_orders", string(orders[:]))
 }
 
 func ComputeHmac256(secret string, message string) string {
@@ -348,35 +516,9 @@ func ComputeHmac256(secret string, message string) string {
     return hex.EncodeToString(h.Sum(nil))
 }
 
+// Request sends api with the given JSON content and blocks until COINUT
+// replies. It is equivalent to RequestCtx(context.Background(), api, content).
 func (client *CoinutClient) Request(api string, content string) (map[string]interface{}, error) {
-    url := "https://api.coinut.com"
-    params := make(map[string]interface{})
-    json.Unmarshal([]byte(content), &params)
-    params["request"] = api
-    params["nonce"] = rand.Int63n(4294967200) + 1
-    data, _ := json.Marshal(params)
-    sig := ComputeHmac256(client.APIKey, string(data[:]))
-    cli := &http.Client{}
-    req, _ := http.NewRequest("POST", url, bytes.NewBuffer(data))
-    req.Header.Add("X-User", client.User)
-    req.Header.Add("X-SIGNATURE", sig)
-    resp, err := cli.Do(req)
-    var result interface{}
-    if err != nil {
-        return make(map[string]interface{}), err
-    } else {
-        defer resp.Body.Close()
-        bodyBytes, _ := ioutil.ReadAll(resp.Body)
-        err := json.Unmarshal(bodyBytes, &result)
-        if err != nil {
-            return make(map[string]interface{}), err
-        }
-        r, ok := result.(map[string]interface{})
-        if ok {
-            return r, nil
-        } else {
-            return map[string]interface{}{"orders": result}, nil
-        }
-    }
+    return client.RequestCtx(context.Background(), api, content)
 }
 
@@ -0,0 +1,136 @@
+package coinut_api
+
+import (
+    "context"
+    "fmt"
+    "time"
+
+    "github.com/coinut/api/models"
+)
+
+// KlinePeriod is a candle width supported by COINUT's historical klines
+// endpoint.
+type KlinePeriod string
+
+const (
+    Kline_1min  KlinePeriod = "1"
+    Kline_5min  KlinePeriod = "5"
+    Kline_15min KlinePeriod = "15"
+    Kline_30min KlinePeriod = "30"
+    Kline_1hour KlinePeriod = "60"
+    Kline_4hour KlinePeriod = "240"
+    Kline_1day  KlinePeriod = "1440"
+)
+
+// maxKlinePageSize is the largest number of candles COINUT returns in a
+// single inst_candle reply.
+const maxKlinePageSize = 500
+
+//    Get historical klines (OHLC candles) for a spot trading instrument.
+//    Args:
+//        inst_id (uint32): the inst_id can be obtained using the GetSpotInstId or GetSpotInstruments functions.
+//        period (KlinePeriod): the candle width, e.g. Kline_1min or Kline_1hour.
+//        size (int): how many candles to return, starting at since. They are
+//            returned in the order COINUT sends them; no sorting is done.
+//        since (time.Time): only return candles at or after this time.
+//
+//    Returns:
+//        the instrument's historical candles
+//
+//    Examples:
+//        import "github.com/coinut/api/go_api"
+//        client := coinut_api.NewClient("your username", "your REST API Key on https://coinut.com/account/settings")
+//        klines, err := client.GetKlines(1, coinut_api.Kline_1hour, 100, time.Now().Add(-24*time.Hour))
+//        if err == nil {
+//            fmt.Println(klines)
+//        }
+//
+//    See also:
+//        https://github.com/coinut/api/wiki/Websocket-API
+func (client *CoinutClient) GetKlines(inst_id uint32, period KlinePeriod, size int, since time.Time) ([]models.Kline, error) {
+    return client.GetKlinesCtx(context.Background(), inst_id, period, size, since)
+}
+
+// GetKlinesCtx is the context-aware equivalent of GetKlines.
+func (client *CoinutClient) GetKlinesCtx(ctx context.Context, inst_id uint32, period KlinePeriod, size int, since time.Time) ([]models.Kline, error) {
+    content := fmt.Sprintf(`{"inst_id":%d,"period":%q,"limit":%d,"start_time":%d}`,
+        inst_id, period, size, since.UnixNano()/int64(time.Microsecond))
+    result, err := client.RequestCtx(ctx, "inst_candle", content)
+    if err != nil {
+        return nil, err
+    }
+    return models.ParseKlines(result)
+}
+
+//    Get the public trade tape for a spot trading instrument.
+//    Args:
+//        inst_id (uint32): the inst_id can be obtained using the GetSpotInstId or GetSpotInstruments functions.
+//        since (int64): only return trades with a trans_id greater than this.
+//
+//    Returns:
+//        the instrument's recent public trades
+//
+//    Examples:
+//        import "github.com/coinut/api/go_api"
+//        client := coinut_api.NewClient("your username", "your REST API Key on https://coinut.com/account/settings")
+//        trades, err := client.GetTrades(1, 0)
+//        if err == nil {
+//            fmt.Println(trades)
+//        }
+//
+//    See also:
+//        https://github.com/coinut/api/wiki/Websocket-API
+func (client *CoinutClient) GetTrades(inst_id uint32, since int64) ([]models.Trade, error) {
+    return client.GetTradesCtx(context.Background(), inst_id, since)
+}
+
+// GetTradesCtx is the context-aware equivalent of GetTrades.
+func (client *CoinutClient) GetTradesCtx(ctx context.Context, inst_id uint32, since int64) ([]models.Trade, error) {
+    content := fmt.Sprintf(`{"inst_id":%d,"last_trans_id":%d}`, inst_id, since)
+    result, err := client.RequestCtx(ctx, "inst_trade", content)
+    if err != nil {
+        return nil, err
+    }
+    return models.ParseTrades(result)
+}
+
+// IterateKlines pages through GetKlinesCtx from `from` to `to`, respecting
+// the client's rate limit, and returns every candle in range. Use it to
+// backfill history wider than a single inst_candle reply covers.
+//
+// Each page is keyed by start_time, so `since` is advanced to one
+// microsecond past the latest candle timestamp seen in the page rather
+// than assuming any particular ordering within the page.
+func (client *CoinutClient) IterateKlines(ctx context.Context, inst_id uint32, period KlinePeriod, from, to time.Time) ([]models.Kline, error) {
+    var all []models.Kline
+    since := from
+    for since.Before(to) {
+        batch, err := client.GetKlinesCtx(ctx, inst_id, period, maxKlinePageSize, since)
+        if err != nil {
+            return all, err
+        }
+        if len(batch) == 0 {
+            break
+        }
+        progressed := false
+        latest := since
+        for _, k := range batch {
+            t := time.Unix(0, k.Timestamp*int64(time.Microsecond))
+            if t.After(to) {
+                continue
+            }
+            if !t.Before(since) {
+                progressed = true
+            }
+            if t.After(latest) {
+                latest = t
+            }
+            all = append(all, k)
+        }
+        if !progressed {
+            break
+        }
+        since = latest.Add(time.Microsecond)
+    }
+    return all, nil
+}
@@ -0,0 +1,61 @@
+package coinut_api
+
+import (
+    "context"
+    "encoding/json"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+    "time"
+)
+
+// TestIterateKlinesProgressesForward pages through two stubbed inst_candle
+// responses and checks since advances forward to `to` instead of
+// re-fetching the same window, regardless of whether a page returns its
+// candles in ascending or descending order.
+func TestIterateKlinesProgressesForward(t *testing.T) {
+    base := time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC)
+    ts := func(minutes int) int64 {
+        return base.Add(time.Duration(minutes) * time.Minute).UnixNano() / int64(time.Microsecond)
+    }
+
+    pages := [][]map[string]interface{}{
+        { // page 1: descending, minutes 2,1,0
+            {"inst_id": 1, "open": "1", "high": "1", "low": "1", "close": "1", "volume": "1", "timestamp": ts(2)},
+            {"inst_id": 1, "open": "1", "high": "1", "low": "1", "close": "1", "volume": "1", "timestamp": ts(1)},
+            {"inst_id": 1, "open": "1", "high": "1", "low": "1", "close": "1", "volume": "1", "timestamp": ts(0)},
+        },
+        { // page 2: ascending, minutes 3,4,5
+            {"inst_id": 1, "open": "1", "high": "1", "low": "1", "close": "1", "volume": "1", "timestamp": ts(3)},
+            {"inst_id": 1, "open": "1", "high": "1", "low": "1", "close": "1", "volume": "1", "timestamp": ts(4)},
+            {"inst_id": 1, "open": "1", "high": "1", "low": "1", "close": "1", "volume": "1", "timestamp": ts(5)},
+        },
+    }
+
+    calls := 0
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        if calls >= len(pages) {
+            json.NewEncoder(w).Encode(map[string]interface{}{"status": []string{"OK"}, "candles": []map[string]interface{}{}})
+            return
+        }
+        candles := pages[calls]
+        calls++
+        json.NewEncoder(w).Encode(map[string]interface{}{"status": []string{"OK"}, "candles": candles})
+    }))
+    defer server.Close()
+
+    client := NewClientWithOptions("user", "key", WithBaseURL(server.URL))
+
+    from := base
+    to := base.Add(5 * time.Minute)
+    klines, err := client.IterateKlines(context.Background(), 1, Kline_1min, from, to)
+    if err != nil {
+        t.Fatalf("IterateKlines: %v", err)
+    }
+    if calls < 2 {
+        t.Fatalf("expected IterateKlines to advance past the first page, only made %d call(s)", calls)
+    }
+    if len(klines) != 6 {
+        t.Fatalf("expected all 6 candles across both pages, got %d", len(klines))
+    }
+}
@@ -0,0 +1,70 @@
+package coinut_api
+
+import (
+    "crypto/rand"
+    "encoding/binary"
+    "sync/atomic"
+    "time"
+)
+
+// NonceSource produces the strictly-increasing nonce COINUT requires on
+// every signed request. The zero value of CoinutClient is never used
+// directly; NewClientWithOptions installs an atomic, time-seeded default.
+type NonceSource interface {
+    Next() uint64
+}
+
+// maxNonce bounds every nonce this package produces to just under 2^32,
+// the range the COINUT endpoint has always accepted (it historically came
+// from rand.Int63n(4294967200) + 1). Nonces outside this range are
+// rejected by the server, so both NonceSource implementations below fold
+// their wider-range inputs (nanosecond/microsecond timestamps) back into
+// it rather than passing them through.
+const maxNonce = 4294967200
+
+// atomicNonce is the default NonceSource: a uint64 counter seeded from
+// wall-clock time on creation, folded into maxNonce, and incremented
+// atomically per request, so concurrent callers on the same client never
+// see the same or a regressing nonce.
+type atomicNonce struct {
+    counter uint64
+}
+
+func newAtomicNonce() *atomicNonce {
+    return &atomicNonce{counter: uint64(time.Now().UnixNano()) % maxNonce}
+}
+
+func (n *atomicNonce) Next() uint64 {
+    return atomic.AddUint64(&n.counter, 1)
+}
+
+// TimestampNonce is an alternative NonceSource that uses microseconds
+// since the Unix epoch directly, rather than an incrementing counter.
+// Monotonicity across rapid-fire requests relies on the clock's
+// resolution; prefer the default atomic source for high request rates.
+type TimestampNonce struct{}
+
+// Next returns the current time as microseconds since the Unix epoch,
+// folded into maxNonce so it stays within the range COINUT accepts.
+func (TimestampNonce) Next() uint64 {
+    return uint64(time.Now().UnixNano()/int64(time.Microsecond))%maxNonce + 1
+}
+
+// WithNonceSource overrides the client's NonceSource, e.g. with
+// TimestampNonce{}.
+func WithNonceSource(source NonceSource) Option {
+    return func(c *CoinutClient) {
+        c.nonceSource = source
+    }
+}
+
+// randomClientOrderID generates a client_ord_id using crypto/rand rather
+// than math/rand, so concurrent goroutines submitting orders at the same
+// moment don't draw from the same predictable sequence and collide.
+func randomClientOrderID() uint32 {
+    var buf [4]byte
+    if _, err := rand.Read(buf[:]); err != nil {
+        return uint32(time.Now().UnixNano())
+    }
+    return binary.BigEndian.Uint32(buf[:])
+}
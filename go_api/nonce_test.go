@@ -0,0 +1,29 @@
+package coinut_api
+
+import "testing"
+
+func TestAtomicNonceMonotonicAndInRange(t *testing.T) {
+    n := newAtomicNonce()
+    if n.counter >= maxNonce {
+        t.Fatalf("seed %d out of accepted range (< %d)", n.counter, maxNonce)
+    }
+
+    var prev uint64
+    for i := 0; i < 1000; i++ {
+        next := n.Next()
+        if next <= prev {
+            t.Fatalf("nonce did not increase: prev=%d next=%d", prev, next)
+        }
+        prev = next
+    }
+}
+
+func TestTimestampNonceInRange(t *testing.T) {
+    var n TimestampNonce
+    for i := 0; i < 10; i++ {
+        v := n.Next()
+        if v == 0 || v > maxNonce {
+            t.Fatalf("nonce %d out of accepted range (0, %d]", v, maxNonce)
+        }
+    }
+}
@@ -0,0 +1,148 @@
+package coinut_api
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+)
+
+// orderParams accumulates the flags set by OrderOptions before
+// CreateNewOrderV2 serializes them into the order JSON.
+type orderParams struct {
+    postOnly      bool
+    ioc           bool
+    fok           bool
+    market        bool
+    clientOrderID uint32
+}
+
+// OrderOption configures an order submitted via CreateNewOrderV2 or
+// SubmitNewOrderV2.
+type OrderOption func(*orderParams)
+
+// WithPostOnly marks the order post-only: it is rejected instead of taking
+// liquidity if it would otherwise match immediately.
+func WithPostOnly() OrderOption {
+    return func(p *orderParams) { p.postOnly = true }
+}
+
+// WithIOC marks the order immediate-or-cancel: any portion that can't be
+// filled immediately is canceled instead of resting on the book.
+func WithIOC() OrderOption {
+    return func(p *orderParams) { p.ioc = true }
+}
+
+// WithFOK marks the order fill-or-kill: it is filled in its entirety
+// immediately or not at all.
+func WithFOK() OrderOption {
+    return func(p *orderParams) { p.fok = true }
+}
+
+// WithMarket makes the order a market order regardless of whether a price
+// was passed to CreateNewOrderV2/SubmitNewOrderV2.
+func WithMarket() OrderOption {
+    return func(p *orderParams) { p.market = true }
+}
+
+// WithClientOrderID sets an explicit client_ord_id instead of letting
+// CreateNewOrderV2 generate a random one.
+func WithClientOrderID(clientOrderID uint32) OrderOption {
+    return func(p *orderParams) { p.clientOrderID = clientOrderID }
+}
+
+//    Create a json string containing the information for opening a new
+//    order, same as CreateNewOrder but with post-only/IOC/FOK flags and an
+//    explicit market option via OrderOption.
+//    Args:
+//        inst_id (uint32): the inst_id can be obtained using the GetSpotInstId or GetSpotInstruments functions.
+//        side (string): either 'BUY' or 'SELL'. It's case sensitive.
+//        qty (float64): the quantity that you want to buy or sell
+//        price (float64): ignored when WithMarket() is passed; otherwise the price of the limit order.
+//        opts (...OrderOption): WithPostOnly, WithIOC, WithFOK, WithMarket, WithClientOrderID.
+//
+//    Returns:
+//        a json string containing the information for the new order
+//
+//    Examples:
+//        import "github.com/coinut/api/go_api"
+//        client := coinut_api.NewClient("your username", "your REST API Key on https://coinut.com/account/settings")
+//        result, err := client.CreateNewOrderV2(1, "BUY", 0.0011, 0.2, coinut_api.WithPostOnly())
+//        if err == nil {
+//            fmt.Println(result)
+//        }
+//        output: {"client_ord_id":3630041631,"inst_id":1,"order_type":"post_only","price":"0.20000000","qty":"0.00110000","side":"BUY"}
+//
+//    See also:
+//        https://github.com/coinut/api/wiki/Websocket-API#ceate-new-order
+func (client *CoinutClient) CreateNewOrderV2(inst_id uint32,
+                      side string,
+                      qty float64,
+                      price float64,
+                      opts ...OrderOption) (string, error) {
+    params := &orderParams{}
+    for _, opt := range opts {
+        opt(params)
+    }
+
+    order := make(map[string]interface{})
+    order["inst_id"] = inst_id
+    order["side"] = side
+    order["qty"] = fmt.Sprintf("%.8f", qty)
+    if !params.market && price > 0.0 {
+        order["price"] = fmt.Sprintf("%.8f", price)
+    }
+    // Only one order_type can be sent; when more than one flag is passed,
+    // post-only takes precedence over IOC, which takes precedence over FOK.
+    switch {
+    case params.postOnly:
+        order["order_type"] = "post_only"
+    case params.ioc:
+        order["order_type"] = "ioc"
+    case params.fok:
+        order["order_type"] = "fok"
+    }
+    if params.clientOrderID > 0 {
+        order["client_ord_id"] = params.clientOrderID
+    } else {
+        order["client_ord_id"] = randomClientOrderID()
+    }
+
+    data, err := json.Marshal(order)
+    if err != nil {
+        return "", err
+    }
+    return string(data), nil
+}
+
+//    Submit an order to the exchange, same as SubmitNewOrder but with
+//    post-only/IOC/FOK flags and an explicit market option via OrderOption.
+//    Args:
+//        inst_id (uint32): the inst_id can be obtained using the get_spot_inst_id or get_spot_instruments functions.
+//        side (string): either 'BUY' or 'SELL'. It's case sensitive.
+//        qty (float64): the quantity that you want to buy or sell
+//        price (float64): ignored when WithMarket() is passed; otherwise the price of the limit order.
+//        opts (...OrderOption): WithPostOnly, WithIOC, WithFOK, WithMarket, WithClientOrderID.
+//
+//    See also:
+//        https://github.com/coinut/api/wiki/Websocket-API#submit-an-order
+func (client *CoinutClient) SubmitNewOrderV2(inst_id uint32,
+                      side string,
+                      qty float64,
+                      price float64,
+                      opts ...OrderOption) (map[string]interface{}, error) {
+    return client.SubmitNewOrderV2Ctx(context.Background(), inst_id, side, qty, price, opts...)
+}
+
+// SubmitNewOrderV2Ctx is the context-aware equivalent of SubmitNewOrderV2.
+func (client *CoinutClient) SubmitNewOrderV2Ctx(ctx context.Context,
+                      inst_id uint32,
+                      side string,
+                      qty float64,
+                      price float64,
+                      opts ...OrderOption) (map[string]interface{}, error) {
+    order, err := client.CreateNewOrderV2(inst_id, side, qty, price, opts...)
+    if err != nil {
+        return make(map[string]interface{}), err
+    }
+    return client.RequestCtx(ctx, "new_order", order)
+}
@@ -0,0 +1,87 @@
+package coinut_api
+
+import (
+    "encoding/json"
+    "testing"
+)
+
+func createOrder(t *testing.T, opts ...OrderOption) map[string]interface{} {
+    t.Helper()
+    client := NewClient("user", "key")
+    raw, err := client.CreateNewOrderV2(1, "BUY", 0.0011, 0.2, opts...)
+    if err != nil {
+        t.Fatalf("CreateNewOrderV2: %v", err)
+    }
+    var order map[string]interface{}
+    if err := json.Unmarshal([]byte(raw), &order); err != nil {
+        t.Fatalf("unmarshal order json: %v", err)
+    }
+    return order
+}
+
+func TestCreateNewOrderV2Flags(t *testing.T) {
+    cases := []struct {
+        name          string
+        opts          []OrderOption
+        wantOrderType interface{}
+    }{
+        {name: "no flags", opts: nil, wantOrderType: nil},
+        {name: "post-only", opts: []OrderOption{WithPostOnly()}, wantOrderType: "post_only"},
+        {name: "ioc", opts: []OrderOption{WithIOC()}, wantOrderType: "ioc"},
+        {name: "fok", opts: []OrderOption{WithFOK()}, wantOrderType: "fok"},
+    }
+
+    for _, c := range cases {
+        t.Run(c.name, func(t *testing.T) {
+            order := createOrder(t, c.opts...)
+            if order["order_type"] != c.wantOrderType {
+                t.Errorf("order_type = %v, want %v", order["order_type"], c.wantOrderType)
+            }
+        })
+    }
+}
+
+func TestCreateNewOrderV2MarketSuppressesPrice(t *testing.T) {
+    order := createOrder(t, WithMarket())
+    if _, ok := order["price"]; ok {
+        t.Errorf("expected no price field for a market order, got %v", order["price"])
+    }
+}
+
+func TestCreateNewOrderV2LimitIncludesPrice(t *testing.T) {
+    order := createOrder(t)
+    if order["price"] != "0.20000000" {
+        t.Errorf("price = %v, want 0.20000000", order["price"])
+    }
+}
+
+// TestCreateNewOrderV2FlagPrecedence documents and locks in the order of
+// precedence when more than one of post-only/IOC/FOK is passed: post-only
+// wins over IOC, which wins over FOK.
+func TestCreateNewOrderV2FlagPrecedence(t *testing.T) {
+    cases := []struct {
+        name string
+        opts []OrderOption
+        want string
+    }{
+        {name: "post-only beats ioc and fok", opts: []OrderOption{WithFOK(), WithIOC(), WithPostOnly()}, want: "post_only"},
+        {name: "ioc beats fok", opts: []OrderOption{WithFOK(), WithIOC()}, want: "ioc"},
+    }
+
+    for _, c := range cases {
+        t.Run(c.name, func(t *testing.T) {
+            order := createOrder(t, c.opts...)
+            if order["order_type"] != c.want {
+                t.Errorf("order_type = %v, want %v", order["order_type"], c.want)
+            }
+        })
+    }
+}
+
+func TestCreateNewOrderV2ExplicitClientOrderID(t *testing.T) {
+    order := createOrder(t, WithClientOrderID(42))
+    id, ok := order["client_ord_id"].(float64)
+    if !ok || id != 42 {
+        t.Errorf("client_ord_id = %v, want 42", order["client_ord_id"])
+    }
+}
@@ -0,0 +1,208 @@
+package coinut_api
+
+import (
+    "bytes"
+    "context"
+    "encoding/json"
+    "fmt"
+    "io/ioutil"
+    "net/http"
+    "strconv"
+    "time"
+
+    "golang.org/x/time/rate"
+)
+
+// defaultRateLimit is COINUT's documented request cap: 8 requests per
+// second per user, with a small burst allowance.
+// See also:
+//     https://github.com/coinut/api/wiki/Websocket-API
+const defaultRateLimit = 8
+
+// defaultMaxRetries is how many times a request is retried on a 5xx or 429
+// response before giving up.
+const defaultMaxRetries = 3
+
+// maxRetryBackoff caps the exponential backoff used between retries when a
+// 5xx/429 response doesn't include a Retry-After header.
+const maxRetryBackoff = 30 * time.Second
+
+// Logger lets callers trace requests and responses at debug level. Pass one
+// in via WithLogger; the default is a no-op.
+type Logger interface {
+    Debugf(format string, args ...interface{})
+}
+
+type noopLogger struct{}
+
+func (noopLogger) Debugf(format string, args ...interface{}) {}
+
+// CoinutError is returned when COINUT replies with a non-"OK" status, e.g.
+// {"status":["ERROR","..."]}. Callers can type-assert it to inspect the
+// raw status slice.
+type CoinutError struct {
+    Request string
+    Status  []string
+}
+
+func (e *CoinutError) Error() string {
+    return fmt.Sprintf("coinut_api: request %q failed: %v", e.Request, e.Status)
+}
+
+// Option configures a CoinutClient created via NewClientWithOptions.
+type Option func(*CoinutClient)
+
+// WithHTTPClient overrides the http.Client used to send requests, e.g. to
+// install a custom Transport or timeout.
+func WithHTTPClient(httpClient *http.Client) Option {
+    return func(c *CoinutClient) {
+        c.httpClient = httpClient
+    }
+}
+
+// WithBaseURL overrides the COINUT API endpoint, mainly for testing against
+// a local mock server.
+func WithBaseURL(baseURL string) Option {
+    return func(c *CoinutClient) {
+        c.baseURL = baseURL
+    }
+}
+
+// WithRateLimit overrides the token-bucket rate limit applied to outgoing
+// requests. requestsPerSecond is the steady-state rate; burst is how many
+// requests may be sent in a single burst above that rate.
+func WithRateLimit(requestsPerSecond float64, burst int) Option {
+    return func(c *CoinutClient) {
+        c.limiter = rate.NewLimiter(rate.Limit(requestsPerSecond), burst)
+    }
+}
+
+// WithMaxRetries overrides how many times a request is retried on a 5xx or
+// 429 response.
+func WithMaxRetries(maxRetries int) Option {
+    return func(c *CoinutClient) {
+        c.maxRetries = maxRetries
+    }
+}
+
+// WithLogger installs a Logger that receives debug-level tracing for every
+// request: method, URL, nonce, and latency.
+func WithLogger(logger Logger) Option {
+    return func(c *CoinutClient) {
+        c.logger = logger
+    }
+}
+
+// RequestCtx is the context-aware equivalent of Request: it sends api with
+// the given JSON content, enforcing the client's rate limit, retrying on
+// 5xx/429 responses with exponential backoff (honoring Retry-After), and
+// surfacing a COINUT-side error status as a *CoinutError.
+func (client *CoinutClient) RequestCtx(ctx context.Context, api string, content string) (map[string]interface{}, error) {
+    params := make(map[string]interface{})
+    json.Unmarshal([]byte(content), &params)
+    params["request"] = api
+    nonce := client.nonceSource.Next()
+    params["nonce"] = nonce
+    data, err := json.Marshal(params)
+    if err != nil {
+        return nil, err
+    }
+    sig := ComputeHmac256(client.APIKey, string(data))
+
+    var lastErr error
+    for attempt := 0; attempt <= client.maxRetries; attempt++ {
+        if err := client.limiter.Wait(ctx); err != nil {
+            return nil, err
+        }
+
+        start := time.Now()
+        req, err := http.NewRequestWithContext(ctx, "POST", client.baseURL, bytes.NewReader(data))
+        if err != nil {
+            return nil, err
+        }
+        req.Header.Add("X-User", client.User)
+        req.Header.Add("X-SIGNATURE", sig)
+
+        resp, err := client.httpClient.Do(req)
+        client.logger.Debugf("coinut_api: %s %s nonce=%d latency=%s err=%v", req.Method, client.baseURL, nonce, time.Since(start), err)
+        if err != nil {
+            lastErr = err
+            continue
+        }
+
+        if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+            retryAfter, explicit := parseRetryAfter(resp.Header.Get("Retry-After"))
+            if !explicit {
+                retryAfter = backoffDuration(attempt)
+            }
+            resp.Body.Close()
+            lastErr = fmt.Errorf("coinut_api: %s: unexpected status %d", api, resp.StatusCode)
+            if attempt == client.maxRetries {
+                break
+            }
+            select {
+            case <-time.After(retryAfter):
+            case <-ctx.Done():
+                return nil, ctx.Err()
+            }
+            continue
+        }
+
+        body, err := readAndClose(resp)
+        if err != nil {
+            return nil, err
+        }
+        if resp.StatusCode != http.StatusOK {
+            return nil, fmt.Errorf("coinut_api: %s: unexpected status %d: %s", api, resp.StatusCode, body)
+        }
+
+        var result interface{}
+        if err := json.Unmarshal(body, &result); err != nil {
+            return nil, err
+        }
+        r, ok := result.(map[string]interface{})
+        if !ok {
+            return map[string]interface{}{"orders": result}, nil
+        }
+        if status, ok := r["status"].([]interface{}); ok && len(status) > 0 {
+            if s, ok := status[0].(string); ok && s != "OK" {
+                strs := make([]string, len(status))
+                for i, v := range status {
+                    strs[i] = fmt.Sprintf("%v", v)
+                }
+                return r, &CoinutError{Request: api, Status: strs}
+            }
+        }
+        return r, nil
+    }
+    return make(map[string]interface{}), lastErr
+}
+
+func readAndClose(resp *http.Response) ([]byte, error) {
+    defer resp.Body.Close()
+    return ioutil.ReadAll(resp.Body)
+}
+
+// parseRetryAfter interprets a Retry-After header given in seconds.
+// explicit is false when the header is absent or malformed, in which case
+// RequestCtx falls back to backoffDuration instead of duration.
+func parseRetryAfter(header string) (duration time.Duration, explicit bool) {
+    if header == "" {
+        return 0, false
+    }
+    if seconds, err := strconv.Atoi(header); err == nil {
+        return time.Duration(seconds) * time.Second, true
+    }
+    return 0, false
+}
+
+// backoffDuration is the exponential delay before retry attempt+1 when the
+// server didn't tell us how long to wait: 1s, 2s, 4s, ..., capped at
+// maxRetryBackoff.
+func backoffDuration(attempt int) time.Duration {
+    d := time.Second * time.Duration(1<<uint(attempt))
+    if d > maxRetryBackoff || d <= 0 {
+        return maxRetryBackoff
+    }
+    return d
+}
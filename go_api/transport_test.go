@@ -0,0 +1,149 @@
+package coinut_api
+
+import (
+    "context"
+    "encoding/json"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+    "time"
+)
+
+func TestRequestCtxRetriesOnTooManyRequests(t *testing.T) {
+    calls := 0
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        calls++
+        if calls < 3 {
+            w.Header().Set("Retry-After", "0")
+            w.WriteHeader(http.StatusTooManyRequests)
+            return
+        }
+        json.NewEncoder(w).Encode(map[string]interface{}{"status": []string{"OK"}})
+    }))
+    defer server.Close()
+
+    client := NewClientWithOptions("user", "key", WithBaseURL(server.URL), WithMaxRetries(3))
+    result, err := client.RequestCtx(context.Background(), "inst_tick", "{}")
+    if err != nil {
+        t.Fatalf("RequestCtx: %v", err)
+    }
+    if calls != 3 {
+        t.Fatalf("expected 3 attempts (2 failures + 1 success), got %d", calls)
+    }
+    status, _ := result["status"].([]interface{})
+    if len(status) == 0 || status[0] != "OK" {
+        t.Fatalf("unexpected result: %+v", result)
+    }
+}
+
+func TestRequestCtxRetriesOnServerError(t *testing.T) {
+    calls := 0
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        calls++
+        if calls < 2 {
+            w.WriteHeader(http.StatusInternalServerError)
+            return
+        }
+        json.NewEncoder(w).Encode(map[string]interface{}{"status": []string{"OK"}})
+    }))
+    defer server.Close()
+
+    client := NewClientWithOptions("user", "key", WithBaseURL(server.URL), WithMaxRetries(3))
+    if _, err := client.RequestCtx(context.Background(), "inst_tick", "{}"); err != nil {
+        t.Fatalf("RequestCtx: %v", err)
+    }
+    if calls != 2 {
+        t.Fatalf("expected 2 attempts (1 failure + 1 success), got %d", calls)
+    }
+}
+
+func TestRequestCtxGivesUpAfterMaxRetries(t *testing.T) {
+    calls := 0
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        calls++
+        w.Header().Set("Retry-After", "0")
+        w.WriteHeader(http.StatusTooManyRequests)
+    }))
+    defer server.Close()
+
+    client := NewClientWithOptions("user", "key", WithBaseURL(server.URL), WithMaxRetries(2))
+    if _, err := client.RequestCtx(context.Background(), "inst_tick", "{}"); err == nil {
+        t.Fatal("expected an error once retries are exhausted")
+    }
+    if calls != 3 { // initial attempt + 2 retries
+        t.Fatalf("expected 3 attempts (initial + 2 retries), got %d", calls)
+    }
+}
+
+func TestParseRetryAfter(t *testing.T) {
+    cases := []struct {
+        header       string
+        wantSeconds  int
+        wantExplicit bool
+    }{
+        {"", 0, false},
+        {"not-a-number", 0, false},
+        {"5", 5, true},
+    }
+    for _, c := range cases {
+        got, explicit := parseRetryAfter(c.header)
+        if explicit != c.wantExplicit {
+            t.Errorf("parseRetryAfter(%q) explicit = %v, want %v", c.header, explicit, c.wantExplicit)
+        }
+        if explicit && got.Seconds() != float64(c.wantSeconds) {
+            t.Errorf("parseRetryAfter(%q) = %v, want %ds", c.header, got, c.wantSeconds)
+        }
+    }
+}
+
+func TestBackoffDuration(t *testing.T) {
+    cases := []struct {
+        attempt int
+        want    time.Duration
+    }{
+        {0, 1 * time.Second},
+        {1, 2 * time.Second},
+        {2, 4 * time.Second},
+        {3, 8 * time.Second},
+        {5, 30 * time.Second}, // 32s, capped at maxRetryBackoff
+        {30, 30 * time.Second},
+    }
+    for _, c := range cases {
+        if got := backoffDuration(c.attempt); got != c.want {
+            t.Errorf("backoffDuration(%d) = %v, want %v", c.attempt, got, c.want)
+        }
+    }
+}
+
+// TestRequestCtxBacksOffExponentiallyWithoutRetryAfter checks that when a
+// 5xx response carries no Retry-After header, RequestCtx actually waits
+// backoffDuration(attempt) between retries instead of a flat delay.
+func TestRequestCtxBacksOffExponentiallyWithoutRetryAfter(t *testing.T) {
+    calls := 0
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        calls++
+        if calls < 3 {
+            w.WriteHeader(http.StatusInternalServerError)
+            return
+        }
+        json.NewEncoder(w).Encode(map[string]interface{}{"status": []string{"OK"}})
+    }))
+    defer server.Close()
+
+    client := NewClientWithOptions("user", "key", WithBaseURL(server.URL), WithMaxRetries(3))
+    start := time.Now()
+    if _, err := client.RequestCtx(context.Background(), "inst_tick", "{}"); err != nil {
+        t.Fatalf("RequestCtx: %v", err)
+    }
+    elapsed := time.Since(start)
+
+    // Two failed attempts with no Retry-After header: backoffDuration(0) +
+    // backoffDuration(1) = 1s + 2s, not a flat 2 * 1s.
+    want := backoffDuration(0) + backoffDuration(1)
+    if elapsed < want {
+        t.Fatalf("elapsed %v, want at least %v (exponential backoff between retries)", elapsed, want)
+    }
+    if elapsed > want+2*time.Second {
+        t.Fatalf("elapsed %v, want close to %v -- backoff may not be growing as expected", elapsed, want)
+    }
+}
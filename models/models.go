@@ -0,0 +1,303 @@
+// Package models provides strongly-typed representations of the COINUT API
+// replies, to be used in place of the raw map[string]interface{} and
+// []interface{} values returned by the lower-level methods on
+// github.com/coinut/api/go_api.CoinutClient.
+package models
+
+import (
+    "fmt"
+
+    "github.com/shopspring/decimal"
+)
+
+// Balance maps a currency symbol (e.g. "BTC", "USDT") to the account's
+// available amount in that currency.
+type Balance map[string]decimal.Decimal
+
+// Instrument describes a spot trading pair.
+type Instrument struct {
+    InstID        uint32 `json:"inst_id"`
+    Base          string `json:"base"`
+    Quote         string `json:"quote"`
+    DecimalPlaces int    `json:"decimal_places"`
+}
+
+// Tick is an instrument's last traded price and surrounding book summary.
+type Tick struct {
+    InstID     uint32
+    Last       decimal.Decimal
+    HighestBuy decimal.Decimal
+    LowestSell decimal.Decimal
+    Volume     decimal.Decimal
+    Timestamp  int64
+}
+
+// OrderbookLevel is a single price level in an orderbook.
+type OrderbookLevel struct {
+    Price decimal.Decimal
+    Qty   decimal.Decimal
+    Count int
+}
+
+// Orderbook is an instrument's full buy/sell book.
+type Orderbook struct {
+    InstID uint32
+    Buy    []OrderbookLevel
+    Sell   []OrderbookLevel
+}
+
+// Order describes a resting or historical order.
+type Order struct {
+    OrderID       uint32
+    InstID        uint32
+    Side          string
+    Price         decimal.Decimal
+    Qty           decimal.Decimal
+    OpenQty       decimal.Decimal
+    ClientOrderID uint32
+}
+
+// OrderAccepted is the reply when a submitted order enters the book.
+type OrderAccepted struct {
+    Order
+    TransID   uint64
+    Timestamp int64
+}
+
+// OrderRejected is the reply when a submitted order is refused.
+type OrderRejected struct {
+    InstID        uint32
+    ClientOrderID uint32
+    Reason        string
+}
+
+// OrderFilled is the reply when a submitted order fills immediately
+// (fully or partially) against the book.
+type OrderFilled struct {
+    Order
+    FillQty   decimal.Decimal
+    TransID   uint64
+    Timestamp int64
+}
+
+// OrderResponse is the result of submitting a new order. Exactly one of
+// Accepted, Rejected, or Filled is non-nil, selected by the Reply field
+// ("order_accepted", "order_rejected", or "order_filled").
+type OrderResponse struct {
+    Reply    string
+    Accepted *OrderAccepted
+    Rejected *OrderRejected
+    Filled   *OrderFilled
+}
+
+// Trade is a single execution from the public trade tape.
+type Trade struct {
+    TransID   uint64
+    InstID    uint32
+    Price     decimal.Decimal
+    Qty       decimal.Decimal
+    Side      string
+    Timestamp int64
+}
+
+// CancelResult is the per-order outcome of a cancel or batch-cancel
+// request.
+type CancelResult struct {
+    OrderID       uint32
+    ClientOrderID uint32
+    Status        string
+}
+
+// Kline is a single OHLC candle for an instrument over one period.
+type Kline struct {
+    InstID    uint32
+    Open      decimal.Decimal
+    High      decimal.Decimal
+    Low       decimal.Decimal
+    Close     decimal.Decimal
+    Volume    decimal.Decimal
+    Timestamp int64
+}
+
+func asString(m map[string]interface{}, key string) string {
+    if v, ok := m[key].(string); ok {
+        return v
+    }
+    return ""
+}
+
+func asUint32(m map[string]interface{}, key string) uint32 {
+    if v, ok := m[key].(float64); ok {
+        return uint32(v)
+    }
+    return 0
+}
+
+func asUint64(m map[string]interface{}, key string) uint64 {
+    if v, ok := m[key].(float64); ok {
+        return uint64(v)
+    }
+    return 0
+}
+
+func asDecimal(m map[string]interface{}, key string) decimal.Decimal {
+    switch v := m[key].(type) {
+    case string:
+        d, err := decimal.NewFromString(v)
+        if err == nil {
+            return d
+        }
+    case float64:
+        return decimal.NewFromFloat(v)
+    }
+    return decimal.Zero
+}
+
+// ParseBalance converts the map returned by CoinutClient.GetBalance into a
+// Balance, skipping the "status" and "nonce" bookkeeping keys.
+func ParseBalance(raw map[string]interface{}) (Balance, error) {
+    balance := make(Balance, len(raw))
+    for symbol, v := range raw {
+        switch symbol {
+        case "status", "nonce", "reply", "trans_id":
+            continue
+        }
+        s, ok := v.(string)
+        if !ok {
+            continue
+        }
+        d, err := decimal.NewFromString(s)
+        if err != nil {
+            return nil, fmt.Errorf("models: parsing balance for %s: %w", symbol, err)
+        }
+        balance[symbol] = d
+    }
+    return balance, nil
+}
+
+func parseOrderbookLevels(raw interface{}) []OrderbookLevel {
+    entries, _ := raw.([]interface{})
+    levels := make([]OrderbookLevel, 0, len(entries))
+    for _, e := range entries {
+        m, ok := e.(map[string]interface{})
+        if !ok {
+            continue
+        }
+        levels = append(levels, OrderbookLevel{
+            Price: asDecimal(m, "price"),
+            Qty:   asDecimal(m, "qty"),
+            Count: int(asUint32(m, "count")),
+        })
+    }
+    return levels
+}
+
+// ParseOrderbook converts the map returned by CoinutClient.GetOrderbook
+// into an Orderbook.
+func ParseOrderbook(raw map[string]interface{}) (*Orderbook, error) {
+    return &Orderbook{
+        InstID: asUint32(raw, "inst_id"),
+        Buy:    parseOrderbookLevels(raw["buy"]),
+        Sell:   parseOrderbookLevels(raw["sell"]),
+    }, nil
+}
+
+// ParseTick converts the map returned by CoinutClient.GetInstTick into a
+// Tick.
+func ParseTick(raw map[string]interface{}) (*Tick, error) {
+    return &Tick{
+        InstID:     asUint32(raw, "inst_id"),
+        Last:       asDecimal(raw, "last"),
+        HighestBuy: asDecimal(raw, "highest_buy"),
+        LowestSell: asDecimal(raw, "lowest_sell"),
+        Volume:     asDecimal(raw, "volume"),
+        Timestamp:  int64(asUint64(raw, "timestamp")),
+    }, nil
+}
+
+func parseOrder(raw map[string]interface{}) Order {
+    return Order{
+        OrderID:       asUint32(raw, "order_id"),
+        InstID:        asUint32(raw, "inst_id"),
+        Side:          asString(raw, "side"),
+        Price:         asDecimal(raw, "price"),
+        Qty:           asDecimal(raw, "qty"),
+        OpenQty:       asDecimal(raw, "open_qty"),
+        ClientOrderID: asUint32(raw, "client_ord_id"),
+    }
+}
+
+// ParseOrderResponse converts the map returned by CoinutClient.SubmitNewOrder
+// into an OrderResponse, selecting the Accepted/Rejected/Filled variant
+// based on the "reply" field.
+func ParseOrderResponse(raw map[string]interface{}) (OrderResponse, error) {
+    reply := asString(raw, "reply")
+    resp := OrderResponse{Reply: reply}
+    switch reply {
+    case "order_rejected":
+        resp.Rejected = &OrderRejected{
+            InstID:        asUint32(raw, "inst_id"),
+            ClientOrderID: asUint32(raw, "client_ord_id"),
+            Reason:        asString(raw, "reason"),
+        }
+    case "order_filled":
+        resp.Filled = &OrderFilled{
+            Order:     parseOrder(raw),
+            FillQty:   asDecimal(raw, "fill_qty"),
+            TransID:   asUint64(raw, "trans_id"),
+            Timestamp: int64(asUint64(raw, "timestamp")),
+        }
+    default:
+        resp.Accepted = &OrderAccepted{
+            Order:     parseOrder(raw),
+            TransID:   asUint64(raw, "trans_id"),
+            Timestamp: int64(asUint64(raw, "timestamp")),
+        }
+    }
+    return resp, nil
+}
+
+// ParseKlines converts the map returned by CoinutClient.GetKlines into a
+// slice of Kline, reading the candles out of the "candles" array.
+func ParseKlines(raw map[string]interface{}) ([]Kline, error) {
+    entries, _ := raw["candles"].([]interface{})
+    klines := make([]Kline, 0, len(entries))
+    for _, e := range entries {
+        m, ok := e.(map[string]interface{})
+        if !ok {
+            continue
+        }
+        klines = append(klines, Kline{
+            InstID:    asUint32(m, "inst_id"),
+            Open:      asDecimal(m, "open"),
+            High:      asDecimal(m, "high"),
+            Low:       asDecimal(m, "low"),
+            Close:     asDecimal(m, "close"),
+            Volume:    asDecimal(m, "volume"),
+            Timestamp: int64(asUint64(m, "timestamp")),
+        })
+    }
+    return klines, nil
+}
+
+// ParseTrades converts the map returned by CoinutClient.GetTrades into a
+// slice of Trade, reading the executions out of the "trades" array.
+func ParseTrades(raw map[string]interface{}) ([]Trade, error) {
+    entries, _ := raw["trades"].([]interface{})
+    trades := make([]Trade, 0, len(entries))
+    for _, e := range entries {
+        m, ok := e.(map[string]interface{})
+        if !ok {
+            continue
+        }
+        trades = append(trades, Trade{
+            TransID:   asUint64(m, "trans_id"),
+            InstID:    asUint32(m, "inst_id"),
+            Price:     asDecimal(m, "price"),
+            Qty:       asDecimal(m, "qty"),
+            Side:      asString(m, "side"),
+            Timestamp: int64(asUint64(m, "timestamp")),
+        })
+    }
+    return trades, nil
+}
@@ -0,0 +1,226 @@
+package models
+
+import (
+    "testing"
+
+    "github.com/shopspring/decimal"
+)
+
+func TestParseBalance(t *testing.T) {
+    cases := []struct {
+        name string
+        raw  map[string]interface{}
+        want Balance
+    }{
+        {
+            name: "mixed currencies and bookkeeping keys",
+            raw: map[string]interface{}{
+                "BTC":    "1.5",
+                "USDT":   "1000.25",
+                "status": []interface{}{"OK"},
+                "nonce":  float64(1),
+            },
+            want: Balance{
+                "BTC":  decimal.RequireFromString("1.5"),
+                "USDT": decimal.RequireFromString("1000.25"),
+            },
+        },
+        {
+            name: "empty",
+            raw:  map[string]interface{}{"status": []interface{}{"OK"}},
+            want: Balance{},
+        },
+    }
+
+    for _, c := range cases {
+        t.Run(c.name, func(t *testing.T) {
+            got, err := ParseBalance(c.raw)
+            if err != nil {
+                t.Fatalf("ParseBalance: %v", err)
+            }
+            if len(got) != len(c.want) {
+                t.Fatalf("got %v, want %v", got, c.want)
+            }
+            for symbol, want := range c.want {
+                if !got[symbol].Equal(want) {
+                    t.Errorf("%s: got %s, want %s", symbol, got[symbol], want)
+                }
+            }
+        })
+    }
+}
+
+func TestParseBalanceInvalidAmount(t *testing.T) {
+    _, err := ParseBalance(map[string]interface{}{"BTC": "not-a-number"})
+    if err == nil {
+        t.Fatal("expected an error for an unparseable amount")
+    }
+}
+
+func TestParseOrderbook(t *testing.T) {
+    raw := map[string]interface{}{
+        "inst_id": float64(1),
+        "buy": []interface{}{
+            map[string]interface{}{"price": "100", "qty": "2", "count": float64(3)},
+        },
+        "sell": []interface{}{
+            map[string]interface{}{"price": "101", "qty": "1", "count": float64(1)},
+        },
+    }
+
+    book, err := ParseOrderbook(raw)
+    if err != nil {
+        t.Fatalf("ParseOrderbook: %v", err)
+    }
+    if book.InstID != 1 {
+        t.Errorf("InstID = %d, want 1", book.InstID)
+    }
+    if len(book.Buy) != 1 || !book.Buy[0].Price.Equal(decimal.RequireFromString("100")) {
+        t.Errorf("Buy = %+v", book.Buy)
+    }
+    if len(book.Sell) != 1 || book.Sell[0].Count != 1 {
+        t.Errorf("Sell = %+v", book.Sell)
+    }
+}
+
+func TestParseTick(t *testing.T) {
+    raw := map[string]interface{}{
+        "inst_id":     float64(42),
+        "last":        "100.5",
+        "highest_buy": "100.4",
+        "lowest_sell": "100.6",
+        "volume":      "12.3",
+        "timestamp":   float64(1234567890),
+    }
+
+    tick, err := ParseTick(raw)
+    if err != nil {
+        t.Fatalf("ParseTick: %v", err)
+    }
+    if tick.InstID != 42 {
+        t.Errorf("InstID = %d, want 42", tick.InstID)
+    }
+    if !tick.Last.Equal(decimal.RequireFromString("100.5")) {
+        t.Errorf("Last = %s, want 100.5", tick.Last)
+    }
+    if tick.Timestamp != 1234567890 {
+        t.Errorf("Timestamp = %d, want 1234567890", tick.Timestamp)
+    }
+}
+
+func TestParseOrderResponse(t *testing.T) {
+    cases := []struct {
+        name  string
+        raw   map[string]interface{}
+        check func(t *testing.T, resp OrderResponse)
+    }{
+        {
+            name: "accepted",
+            raw: map[string]interface{}{
+                "reply":   "order_accepted",
+                "inst_id": float64(1),
+                "side":    "BUY",
+                "price":   "100",
+                "qty":     "1",
+            },
+            check: func(t *testing.T, resp OrderResponse) {
+                if resp.Accepted == nil {
+                    t.Fatal("expected Accepted to be set")
+                }
+                if resp.Rejected != nil || resp.Filled != nil {
+                    t.Fatal("expected only Accepted to be set")
+                }
+            },
+        },
+        {
+            name: "rejected",
+            raw: map[string]interface{}{
+                "reply":   "order_rejected",
+                "inst_id": float64(1),
+                "reason":  "no_balance",
+            },
+            check: func(t *testing.T, resp OrderResponse) {
+                if resp.Rejected == nil || resp.Rejected.Reason != "no_balance" {
+                    t.Fatalf("unexpected Rejected: %+v", resp.Rejected)
+                }
+                if resp.Accepted != nil || resp.Filled != nil {
+                    t.Fatal("expected only Rejected to be set")
+                }
+            },
+        },
+        {
+            name: "filled",
+            raw: map[string]interface{}{
+                "reply":    "order_filled",
+                "inst_id":  float64(1),
+                "fill_qty": "1",
+            },
+            check: func(t *testing.T, resp OrderResponse) {
+                if resp.Filled == nil || !resp.Filled.FillQty.Equal(decimal.RequireFromString("1")) {
+                    t.Fatalf("unexpected Filled: %+v", resp.Filled)
+                }
+                if resp.Accepted != nil || resp.Rejected != nil {
+                    t.Fatal("expected only Filled to be set")
+                }
+            },
+        },
+    }
+
+    for _, c := range cases {
+        t.Run(c.name, func(t *testing.T) {
+            resp, err := ParseOrderResponse(c.raw)
+            if err != nil {
+                t.Fatalf("ParseOrderResponse: %v", err)
+            }
+            if resp.Reply != c.raw["reply"] {
+                t.Errorf("Reply = %q, want %q", resp.Reply, c.raw["reply"])
+            }
+            c.check(t, resp)
+        })
+    }
+}
+
+func TestParseKlines(t *testing.T) {
+    raw := map[string]interface{}{
+        "candles": []interface{}{
+            map[string]interface{}{
+                "inst_id": float64(1), "open": "1", "high": "2", "low": "0.5", "close": "1.5",
+                "volume": "10", "timestamp": float64(1000),
+            },
+            "not a candle",
+        },
+    }
+
+    klines, err := ParseKlines(raw)
+    if err != nil {
+        t.Fatalf("ParseKlines: %v", err)
+    }
+    if len(klines) != 1 {
+        t.Fatalf("got %d klines, want 1 (malformed entry should be skipped)", len(klines))
+    }
+    if klines[0].Timestamp != 1000 || !klines[0].High.Equal(decimal.RequireFromString("2")) {
+        t.Errorf("unexpected kline: %+v", klines[0])
+    }
+}
+
+func TestParseTrades(t *testing.T) {
+    raw := map[string]interface{}{
+        "trades": []interface{}{
+            map[string]interface{}{
+                "trans_id": float64(7), "inst_id": float64(1), "price": "100",
+                "qty": "2", "side": "SELL", "timestamp": float64(555),
+            },
+        },
+    }
+
+    trades, err := ParseTrades(raw)
+    if err != nil {
+        t.Fatalf("ParseTrades: %v", err)
+    }
+    if len(trades) != 1 {
+        t.Fatalf("got %d trades, want 1", len(trades))
+    }
+    if trades[0].TransID != 7 || trades[0].Side != "SELL" {
+        t.Errorf("unexpected trade: %+v", trades[0])
+    }
+}
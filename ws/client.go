@@ -0,0 +1,458 @@
+// Package ws provides a persistent, subscription-based WebSocket client for
+// the COINUT streaming API, complementing the request/response REST client
+// in github.com/coinut/api/go_api.
+package ws
+
+import (
+    "encoding/json"
+    "fmt"
+    "log"
+    "net/url"
+    "sync"
+    "time"
+
+    "github.com/coinut/api/go_api"
+    "github.com/gorilla/websocket"
+)
+
+const defaultURL = "wss://wsapi.coinut.com"
+
+// Tick is a single realtime price tick pushed for a subscribed instrument.
+type Tick struct {
+    InstID     uint32 `json:"inst_id"`
+    Last       string `json:"last"`
+    HighestBuy string `json:"highest_buy"`
+    LowestSell string `json:"lowest_sell"`
+    Volume     string `json:"volume"`
+    Timestamp  int64  `json:"timestamp"`
+}
+
+// OrderbookLevel is a single price level in an orderbook update.
+type OrderbookLevel struct {
+    Price string `json:"price"`
+    Qty   string `json:"qty"`
+    Count int    `json:"count"`
+}
+
+// OrderbookUpdate is a push notification carrying either a full snapshot or
+// an incremental change to an instrument's orderbook.
+type OrderbookUpdate struct {
+    InstID uint32           `json:"inst_id"`
+    Buy    []OrderbookLevel `json:"buy"`
+    Sell   []OrderbookLevel `json:"sell"`
+}
+
+// OrderEvent is a push notification about the caller's own orders, such as
+// an order being accepted, filled, or rejected. The "status" COINUT sends
+// on every reply (e.g. status:["OK"]) is deliberately not decoded here: it
+// is an array like everywhere else in this API, and a single mismatched
+// field would otherwise make decode() drop the whole event.
+type OrderEvent struct {
+    Reply   string `json:"reply"`
+    InstID  uint32 `json:"inst_id"`
+    OrderID uint32 `json:"order_id"`
+    Side    string `json:"side"`
+    Price   string `json:"price"`
+    Qty     string `json:"qty"`
+    OpenQty string `json:"open_qty"`
+    FillQty string `json:"fill_qty"`
+}
+
+// subscription remembers how to re-issue a subscribe request after a
+// reconnect, and where to deliver decoded messages for it. replies lists
+// every value of the push message's "reply" field that should be routed
+// to this subscription — for most subscriptions that's just the request
+// name echoed back, but e.g. user_order pushes arrive tagged with the
+// specific order event ("order_accepted", "order_filled", "order_rejected")
+// rather than the request name.
+type subscription struct {
+    request string
+    params  map[string]interface{}
+    deliver func(map[string]interface{})
+    replies []string
+}
+
+// CoinutWSClient is a long-lived WebSocket connection to the COINUT
+// streaming API. It multiplexes requests by nonce, logs in automatically on
+// connect, and transparently reconnects with backoff on disconnect,
+// replaying any previously registered subscriptions.
+//
+// Examples:
+//     import "github.com/coinut/api/ws"
+//     client := ws.NewClient("your username", "your REST API Key on https://coinut.com/account/settings")
+//     if err := client.Connect(); err != nil {
+//         log.Fatal(err)
+//     }
+//     ticks, err := client.SubscribeInstTick(1)
+//     if err == nil {
+//         for tick := range ticks {
+//             fmt.Println(tick)
+//         }
+//     }
+type CoinutWSClient struct {
+    APIKey string
+    User   string
+    URL    string
+
+    mu         sync.Mutex
+    conn       *websocket.Conn
+    readDone   chan struct{}
+    nonce      uint32
+    pending    map[uint32]chan map[string]interface{}
+    subs       []*subscription
+    replyIndex map[string]*subscription
+    closed     bool
+    writeMu    sync.Mutex
+}
+
+// NewClient creates a COINUT WebSocket client for the given user and API
+// key. Call Connect before subscribing to anything.
+func NewClient(user string, key string) *CoinutWSClient {
+    return &CoinutWSClient{
+        APIKey:     key,
+        User:       user,
+        URL:        defaultURL,
+        pending:    make(map[uint32]chan map[string]interface{}),
+        replyIndex: make(map[string]*subscription),
+    }
+}
+
+// Connect dials the COINUT WebSocket endpoint, logs in, and starts the
+// background read loop, heartbeat, and reconnect-with-backoff supervisor.
+// It blocks until the initial connection and login succeed.
+func (client *CoinutWSClient) Connect() error {
+    if err := client.dialAndLogin(); err != nil {
+        return err
+    }
+    go client.supervise()
+    return nil
+}
+
+// Close shuts down the connection and stops all background goroutines.
+// Channels returned by Subscribe* calls are not closed.
+func (client *CoinutWSClient) Close() error {
+    client.mu.Lock()
+    client.closed = true
+    conn := client.conn
+    client.mu.Unlock()
+    if conn != nil {
+        return conn.Close()
+    }
+    return nil
+}
+
+func (client *CoinutWSClient) dialAndLogin() error {
+    u, err := url.Parse(client.URL)
+    if err != nil {
+        return err
+    }
+    conn, _, err := websocket.DefaultDialer.Dial(u.String(), nil)
+    if err != nil {
+        return err
+    }
+
+    done := make(chan struct{})
+    client.mu.Lock()
+    if client.closed {
+        client.mu.Unlock()
+        conn.Close()
+        return fmt.Errorf("coinut ws: client closed")
+    }
+    client.conn = conn
+    client.readDone = done
+    client.mu.Unlock()
+
+    go client.readLoop(conn, done)
+    go client.heartbeat(conn)
+
+    nonce := client.nextNonce()
+    login := map[string]interface{}{
+        "request": "login",
+        "nonce":   nonce,
+        "user":    client.User,
+    }
+    data, _ := json.Marshal(login)
+    sig := coinut_api.ComputeHmac256(client.APIKey, string(data))
+
+    loginWithSig := map[string]interface{}{
+        "request":   "login",
+        "nonce":     nonce,
+        "user":      client.User,
+        "signature": sig,
+    }
+    reply, err := client.call(conn, nonce, loginWithSig)
+    if err != nil {
+        return err
+    }
+    if status, ok := reply["status"].([]interface{}); ok && len(status) > 0 && status[0] != "OK" {
+        return fmt.Errorf("coinut ws login failed: %v", status)
+    }
+    return nil
+}
+
+// supervise watches the connection and reconnects with exponential backoff
+// when it drops, re-issuing every registered subscription afterwards.
+func (client *CoinutWSClient) supervise() {
+    backoff := time.Second
+    const maxBackoff = 30 * time.Second
+    for {
+        client.mu.Lock()
+        conn := client.conn
+        done := client.readDone
+        closed := client.closed
+        client.mu.Unlock()
+        if closed {
+            return
+        }
+        if conn != nil {
+            <-done
+        }
+
+        client.mu.Lock()
+        if client.closed {
+            client.mu.Unlock()
+            return
+        }
+        client.mu.Unlock()
+
+        time.Sleep(backoff)
+        if err := client.dialAndLogin(); err != nil {
+            log.Printf("coinut ws: reconnect failed: %v", err)
+            backoff *= 2
+            if backoff > maxBackoff {
+                backoff = maxBackoff
+            }
+            continue
+        }
+        backoff = time.Second
+        client.resubscribeAll()
+    }
+}
+
+func (client *CoinutWSClient) heartbeat(conn *websocket.Conn) {
+    ticker := time.NewTicker(30 * time.Second)
+    defer ticker.Stop()
+    for range ticker.C {
+        client.writeMu.Lock()
+        err := conn.WriteMessage(websocket.PingMessage, nil)
+        client.writeMu.Unlock()
+        if err != nil {
+            return
+        }
+        client.mu.Lock()
+        current := client.conn
+        client.mu.Unlock()
+        if current != conn {
+            return
+        }
+    }
+}
+
+// readLoop is the sole goroutine that ever calls conn's read methods, per
+// gorilla/websocket's concurrency contract. It closes done when the
+// connection dies so supervise knows to reconnect.
+func (client *CoinutWSClient) readLoop(conn *websocket.Conn, done chan struct{}) {
+    defer close(done)
+    for {
+        _, message, err := conn.ReadMessage()
+        if err != nil {
+            return
+        }
+        var msg map[string]interface{}
+        if err := json.Unmarshal(message, &msg); err != nil {
+            continue
+        }
+        client.dispatch(msg)
+    }
+}
+
+func (client *CoinutWSClient) dispatch(msg map[string]interface{}) {
+    if nonce, ok := msg["nonce"]; ok {
+        var n uint32
+        switch v := nonce.(type) {
+        case float64:
+            n = uint32(v)
+        case uint32:
+            n = v
+        }
+        client.mu.Lock()
+        ch, ok := client.pending[n]
+        if ok {
+            delete(client.pending, n)
+        }
+        client.mu.Unlock()
+        if ok {
+            ch <- msg
+            return
+        }
+    }
+
+    reply, _ := msg["reply"].(string)
+    client.mu.Lock()
+    sub, ok := client.replyIndex[reply]
+    client.mu.Unlock()
+    if ok {
+        sub.deliver(msg)
+    }
+}
+
+func (client *CoinutWSClient) nextNonce() uint32 {
+    client.mu.Lock()
+    defer client.mu.Unlock()
+    client.nonce++
+    return client.nonce
+}
+
+// call sends a request over conn and blocks for the matching reply, keyed
+// by nonce.
+func (client *CoinutWSClient) call(conn *websocket.Conn, nonce uint32, req map[string]interface{}) (map[string]interface{}, error) {
+    ch := make(chan map[string]interface{}, 1)
+    client.mu.Lock()
+    client.pending[nonce] = ch
+    client.mu.Unlock()
+
+    data, err := json.Marshal(req)
+    if err != nil {
+        return nil, err
+    }
+    client.writeMu.Lock()
+    err = conn.WriteMessage(websocket.TextMessage, data)
+    client.writeMu.Unlock()
+    if err != nil {
+        return nil, err
+    }
+
+    select {
+    case reply := <-ch:
+        return reply, nil
+    case <-time.After(10 * time.Second):
+        client.mu.Lock()
+        delete(client.pending, nonce)
+        client.mu.Unlock()
+        return nil, fmt.Errorf("coinut ws: request %v timed out", req["request"])
+    }
+}
+
+func (client *CoinutWSClient) resubscribeAll() {
+    client.mu.Lock()
+    conn := client.conn
+    subs := make([]*subscription, len(client.subs))
+    copy(subs, client.subs)
+    client.mu.Unlock()
+
+    for _, sub := range subs {
+        nonce := client.nextNonce()
+        req := make(map[string]interface{}, len(sub.params)+2)
+        for k, v := range sub.params {
+            req[k] = v
+        }
+        req["request"] = sub.request
+        req["nonce"] = nonce
+        if _, err := client.call(conn, nonce, req); err != nil {
+            log.Printf("coinut ws: resubscribe %s failed: %v", sub.request, err)
+        }
+    }
+}
+
+// SubscribeInstTick subscribes to realtime ticks for inst_id and returns a
+// channel that receives one Tick per update. The subscription is
+// automatically replayed after a reconnect.
+//
+// See also:
+//     https://github.com/coinut/api/wiki/Websocket-API#get-realtime-ticks
+func (client *CoinutWSClient) SubscribeInstTick(inst_id uint32) (<-chan Tick, error) {
+    out := make(chan Tick, 64)
+    params := map[string]interface{}{"inst_id": inst_id, "subscribe": true}
+    if err := client.subscribe("inst_tick", params, func(msg map[string]interface{}) {
+        var tick Tick
+        if decode(msg, &tick) {
+            out <- tick
+        }
+    }, "inst_tick"); err != nil {
+        return nil, err
+    }
+    return out, nil
+}
+
+// SubscribeOrderbook subscribes to orderbook updates for inst_id and
+// returns a channel that receives one OrderbookUpdate per change. The
+// subscription is automatically replayed after a reconnect.
+//
+// See also:
+//     https://github.com/coinut/api/wiki/Websocket-API#get-orderbooks-in-realtime
+func (client *CoinutWSClient) SubscribeOrderbook(inst_id uint32) (<-chan OrderbookUpdate, error) {
+    out := make(chan OrderbookUpdate, 64)
+    params := map[string]interface{}{"inst_id": inst_id, "subscribe": true}
+    if err := client.subscribe("inst_order_book", params, func(msg map[string]interface{}) {
+        var update OrderbookUpdate
+        if decode(msg, &update) {
+            out <- update
+        }
+    }, "inst_order_book"); err != nil {
+        return nil, err
+    }
+    return out, nil
+}
+
+// SubscribeUserOrders subscribes to the logged-in user's own order events
+// (acceptance, fills, rejections) and returns a channel that receives
+// one OrderEvent per update. Pushes for this subscription arrive tagged
+// with the specific event ("order_accepted", "order_filled",
+// "order_rejected"), not the "user_order" request name. The subscription
+// is automatically replayed after a reconnect.
+//
+// See also:
+//     https://github.com/coinut/api/wiki/Websocket-API#submit-an-order
+func (client *CoinutWSClient) SubscribeUserOrders() (<-chan OrderEvent, error) {
+    out := make(chan OrderEvent, 64)
+    params := map[string]interface{}{"subscribe": true}
+    if err := client.subscribe("user_order", params, func(msg map[string]interface{}) {
+        var event OrderEvent
+        if decode(msg, &event) {
+            out <- event
+        }
+    }, "order_accepted", "order_filled", "order_rejected"); err != nil {
+        return nil, err
+    }
+    return out, nil
+}
+
+// subscribe issues a subscribe request and, once COINUT acknowledges it,
+// registers deliver to receive every future push whose "reply" field
+// matches one of replies.
+func (client *CoinutWSClient) subscribe(request string, params map[string]interface{}, deliver func(map[string]interface{}), replies ...string) error {
+    client.mu.Lock()
+    conn := client.conn
+    client.mu.Unlock()
+    if conn == nil {
+        return fmt.Errorf("coinut ws: not connected")
+    }
+
+    nonce := client.nextNonce()
+    req := make(map[string]interface{}, len(params)+2)
+    for k, v := range params {
+        req[k] = v
+    }
+    req["request"] = request
+    req["nonce"] = nonce
+    if _, err := client.call(conn, nonce, req); err != nil {
+        return err
+    }
+
+    sub := &subscription{request: request, params: params, deliver: deliver, replies: replies}
+    client.mu.Lock()
+    client.subs = append(client.subs, sub)
+    for _, reply := range replies {
+        client.replyIndex[reply] = sub
+    }
+    client.mu.Unlock()
+    return nil
+}
+
+func decode(msg map[string]interface{}, out interface{}) bool {
+    data, err := json.Marshal(msg)
+    if err != nil {
+        return false
+    }
+    return json.Unmarshal(data, out) == nil
+}
@@ -0,0 +1,45 @@
+package ws
+
+import "testing"
+
+func TestDispatchRoutesOrderEventByReply(t *testing.T) {
+    client := NewClient("user", "key")
+
+    received := make(chan OrderEvent, 1)
+    sub := &subscription{
+        request: "user_order",
+        deliver: func(msg map[string]interface{}) {
+            var event OrderEvent
+            if decode(msg, &event) {
+                received <- event
+            }
+        },
+        replies: []string{"order_accepted", "order_filled", "order_rejected"},
+    }
+    client.subs = append(client.subs, sub)
+    for _, reply := range sub.replies {
+        client.replyIndex[reply] = sub
+    }
+
+    client.dispatch(map[string]interface{}{
+        "reply":    "order_filled",
+        "inst_id":  float64(1),
+        "order_id": float64(42),
+        "side":     "BUY",
+        "status":   []interface{}{"OK"},
+    })
+
+    select {
+    case event := <-received:
+        if event.Reply != "order_filled" || event.OrderID != 42 {
+            t.Fatalf("unexpected event: %+v", event)
+        }
+    default:
+        t.Fatal("expected order_filled push to be delivered to SubscribeUserOrders' channel")
+    }
+}
+
+func TestDispatchIgnoresUnregisteredReply(t *testing.T) {
+    client := NewClient("user", "key")
+    client.dispatch(map[string]interface{}{"reply": "inst_tick", "inst_id": float64(1)})
+}